@@ -0,0 +1,92 @@
+package otintegration
+
+import (
+	"context"
+	"io"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/gin-gonic/gin"
+	"github.com/opentracing/opentracing-go"
+)
+
+// Backend knows how to set up an opentracing.Tracer for a concrete tracing
+// system (Jaeger, Zipkin, ...). Implementations live under
+// otintegration/backend/<name>.
+type Backend interface {
+	// Setup creates a tracer for the given component name, along with an
+	// io.Closer that must be called to flush and release its resources.
+	Setup(componentName string) (opentracing.Tracer, io.Closer, error)
+}
+
+// Tracing owns a tracer obtained from a Backend, so callers get a single
+// place to set it up and a single place to shut it down.
+type Tracing struct {
+	ServiceName   string
+	SpanNameLimit int
+
+	tracer opentracing.Tracer
+	closer io.Closer
+}
+
+// NewTracing sets up the given Backend for serviceName and returns a ready
+// to use Tracing. spanNameLimit is kept for callers that want to cap span
+// name cardinality and is exposed as Tracing.SpanNameLimit.
+func NewTracing(serviceName string, spanNameLimit int, backend Backend) (*Tracing, error) {
+	tracer, closer, err := backend.Setup(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tracing{
+		ServiceName:   serviceName,
+		SpanNameLimit: spanNameLimit,
+		tracer:        tracer,
+		closer:        closer,
+	}, nil
+}
+
+// Tracer returns the underlying opentracing.Tracer.
+func (t *Tracing) Tracer() opentracing.Tracer {
+	return t.tracer
+}
+
+// Close flushes and releases the resources held by the underlying tracer.
+func (t *Tracing) Close() error {
+	if t.closer == nil {
+		return nil
+	}
+	return t.closer.Close()
+}
+
+type tracingContextKey struct{}
+
+// WithTracing returns a copy of ctx carrying t, retrievable with FromContext.
+func WithTracing(ctx context.Context, t *Tracing) context.Context {
+	return context.WithValue(ctx, tracingContextKey{}, t)
+}
+
+// FromContext returns the Tracing stored in ctx by WithTracing, if any.
+func FromContext(ctx context.Context) (*Tracing, bool) {
+	t, ok := ctx.Value(tracingContextKey{}).(*Tracing)
+	return t, ok
+}
+
+// OpenTracerGinMiddlewareWithTracing builds OpenTracerGinMiddleware from a
+// *Tracing so callers don't need to hand-wire a tracer themselves. The
+// middleware's span name limit defaults to t.SpanNameLimit; pass
+// WithSpanNameLimit in opts to override it.
+func OpenTracerGinMiddlewareWithTracing(operationPrefix string, t *Tracing, opts ...MiddlewareOption) gin.HandlerFunc {
+	return OpenTracerGinMiddleware(operationPrefix, t.Tracer(), withTracingDefaults(t, opts)...)
+}
+
+// OpenTracerGorestMiddlewareWithTracing builds OpenTracerGorestMiddleware
+// from a *Tracing so callers don't need to hand-wire a tracer themselves.
+// The middleware's span name limit defaults to t.SpanNameLimit; pass
+// WithSpanNameLimit in opts to override it.
+func OpenTracerGorestMiddlewareWithTracing(operationPrefix string, t *Tracing, opts ...MiddlewareOption) rest.MiddlewareSimple {
+	return OpenTracerGorestMiddleware(operationPrefix, t.Tracer(), withTracingDefaults(t, opts)...)
+}
+
+func withTracingDefaults(t *Tracing, opts []MiddlewareOption) []MiddlewareOption {
+	return append([]MiddlewareOption{WithSpanNameLimit(t.SpanNameLimit)}, opts...)
+}
@@ -0,0 +1,69 @@
+// Package jaeger provides an otintegration.Backend that sets up a Jaeger
+// tracer via github.com/uber/jaeger-client-go.
+package jaeger
+
+import (
+	"io"
+
+	"github.com/opentracing/opentracing-go"
+	jaeger "github.com/uber/jaeger-client-go"
+	jaegercfg "github.com/uber/jaeger-client-go/config"
+)
+
+// Backend configures and builds a Jaeger opentracing.Tracer. The zero value
+// reports to a local jaeger-agent on its default port and samples every
+// trace, which is fine for development.
+type Backend struct {
+	// LocalAgentHostPort is the host:port of the jaeger-agent to report
+	// spans to. Defaults to jaeger-client-go's own default when empty.
+	LocalAgentHostPort string
+
+	// SamplerType is one of "const", "probabilistic", "rateLimiting" or
+	// "remote". Defaults to "const".
+	SamplerType string
+
+	// SamplerParam is interpreted according to SamplerType, see
+	// jaegercfg.SamplerConfig. Defaults to 1 (always sample) when
+	// SamplerType is "const"; other sampler types use the value as given.
+	SamplerParam float64
+
+	// LogSpans enables a LoggingReporter that runs alongside the main
+	// reporter and logs every submitted span.
+	LogSpans bool
+}
+
+// Setup implements otintegration.Backend.
+func (b *Backend) Setup(componentName string) (opentracing.Tracer, io.Closer, error) {
+	const samplerTypeConst = "const"
+
+	samplerType := b.SamplerType
+	if samplerType == "" {
+		samplerType = samplerTypeConst
+	}
+
+	samplerParam := b.SamplerParam
+	if samplerType == samplerTypeConst && samplerParam == 0 {
+		samplerParam = 1
+	}
+
+	cfg := jaegercfg.Configuration{
+		ServiceName: componentName,
+		Sampler: &jaegercfg.SamplerConfig{
+			Type:  samplerType,
+			Param: samplerParam,
+		},
+		Reporter: &jaegercfg.ReporterConfig{
+			LogSpans:           b.LogSpans,
+			LocalAgentHostPort: b.LocalAgentHostPort,
+		},
+	}
+
+	// jaeger-client-go's default logger discards everything, so the
+	// LoggingReporter needs a real logger for LogSpans to have any effect.
+	var opts []jaegercfg.Option
+	if b.LogSpans {
+		opts = append(opts, jaegercfg.Logger(jaeger.StdLogger))
+	}
+
+	return cfg.NewTracer(opts...)
+}
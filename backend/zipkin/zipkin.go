@@ -0,0 +1,45 @@
+// Package zipkin provides an otintegration.Backend that sets up a Zipkin
+// tracer via github.com/openzipkin/zipkin-go, bridged to opentracing with
+// github.com/openzipkin-contrib/zipkin-go-opentracing.
+package zipkin
+
+import (
+	"io"
+
+	"github.com/opentracing/opentracing-go"
+	zipkinot "github.com/openzipkin-contrib/zipkin-go-opentracing"
+	"github.com/openzipkin/zipkin-go"
+	zipkinhttp "github.com/openzipkin/zipkin-go/reporter/http"
+)
+
+// Backend configures and builds a Zipkin opentracing.Tracer.
+type Backend struct {
+	// ReporterURL is the Zipkin collector HTTP endpoint, e.g.
+	// "http://localhost:9411/api/v2/spans".
+	ReporterURL string
+
+	// HostPort is advertised to Zipkin as this service's local endpoint,
+	// e.g. "0.0.0.0:80". Optional.
+	HostPort string
+}
+
+// Setup implements otintegration.Backend.
+func (b *Backend) Setup(componentName string) (opentracing.Tracer, io.Closer, error) {
+	reporter := zipkinhttp.NewReporter(b.ReporterURL)
+
+	endpoint, err := zipkin.NewEndpoint(componentName, b.HostPort)
+	if err != nil {
+		reporter.Close()
+		return nil, nil, err
+	}
+
+	nativeTracer, err := zipkin.NewTracer(reporter, zipkin.WithLocalEndpoint(endpoint))
+	if err != nil {
+		reporter.Close()
+		return nil, nil, err
+	}
+
+	tracer := zipkinot.Wrap(nativeTracer)
+
+	return tracer, reporter, nil
+}
@@ -0,0 +1,82 @@
+package otintegration
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+type fakeCloser struct {
+	closed bool
+}
+
+func (c *fakeCloser) Close() error {
+	c.closed = true
+	return nil
+}
+
+type fakeBackend struct {
+	tracer opentracing.Tracer
+	closer io.Closer
+	err    error
+}
+
+func (b *fakeBackend) Setup(componentName string) (opentracing.Tracer, io.Closer, error) {
+	return b.tracer, b.closer, b.err
+}
+
+func TestNewTracing(t *testing.T) {
+	closer := &fakeCloser{}
+	backend := &fakeBackend{tracer: mocktracer.New(), closer: closer}
+
+	tracing, err := NewTracing("my-service", 64, backend)
+	if err != nil {
+		t.Fatalf("NewTracing() error = %v", err)
+	}
+	if tracing.ServiceName != "my-service" {
+		t.Errorf("ServiceName = %q, want my-service", tracing.ServiceName)
+	}
+	if tracing.SpanNameLimit != 64 {
+		t.Errorf("SpanNameLimit = %d, want 64", tracing.SpanNameLimit)
+	}
+	if tracing.Tracer() != backend.tracer {
+		t.Errorf("Tracer() did not return the backend's tracer")
+	}
+
+	if err := tracing.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if !closer.closed {
+		t.Errorf("expected the backend's closer to be closed")
+	}
+}
+
+func TestNewTracing_SetupError(t *testing.T) {
+	wantErr := errors.New("boom")
+	backend := &fakeBackend{err: wantErr}
+
+	if _, err := NewTracing("my-service", 0, backend); !errors.Is(err, wantErr) {
+		t.Fatalf("NewTracing() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestTracingContext(t *testing.T) {
+	tracing := &Tracing{ServiceName: "my-service"}
+
+	if _, ok := FromContext(context.Background()); ok {
+		t.Fatalf("FromContext() on an empty context should not find a Tracing")
+	}
+
+	ctx := WithTracing(context.Background(), tracing)
+	got, ok := FromContext(ctx)
+	if !ok {
+		t.Fatalf("FromContext() did not find the Tracing stored by WithTracing()")
+	}
+	if got != tracing {
+		t.Errorf("FromContext() = %v, want %v", got, tracing)
+	}
+}
@@ -0,0 +1,88 @@
+package grpctracing
+
+import (
+	"context"
+	"io"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that starts a
+// client-kind span for every outgoing unary call and injects it into the
+// outgoing metadata so the server can pick it up as its parent.
+func UnaryClientInterceptor(tracer opentracing.Tracer) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		span, ctx := startClientSpan(ctx, tracer, method)
+		defer span.Finish()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err != nil {
+			setSpanError(span, err)
+		}
+		return err
+	}
+}
+
+// StreamClientInterceptor returns a grpc.StreamClientInterceptor analogous
+// to UnaryClientInterceptor for streaming calls.
+func StreamClientInterceptor(tracer opentracing.Tracer) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		span, ctx := startClientSpan(ctx, tracer, method)
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			setSpanError(span, err)
+			span.Finish()
+			return nil, err
+		}
+		return &tracedClientStream{ClientStream: stream, span: span}, nil
+	}
+}
+
+func startClientSpan(ctx context.Context, tracer opentracing.Tracer, method string) (opentracing.Span, context.Context) {
+	opts := []opentracing.StartSpanOption{ext.SpanKindRPCClient}
+	if parentSpan := opentracing.SpanFromContext(ctx); parentSpan != nil {
+		opts = append(opts, opentracing.ChildOf(parentSpan.Context()))
+	}
+	span := tracer.StartSpan(method, opts...)
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		md = metadata.MD{}
+	} else {
+		md = md.Copy()
+	}
+	_ = tracer.Inject(span.Context(), opentracing.TextMap, metadataTextMap(md))
+
+	return span, metadata.NewOutgoingContext(ctx, md)
+}
+
+// tracedClientStream finishes the client span once RecvMsg returns an
+// error, including the io.EOF that ends the stream. CloseSend only tags a
+// failed close as an error; it does not finish the span.
+type tracedClientStream struct {
+	grpc.ClientStream
+	span opentracing.Span
+}
+
+func (s *tracedClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		if err != io.EOF {
+			setSpanError(s.span, err)
+		}
+		s.span.Finish()
+	}
+	return err
+}
+
+func (s *tracedClientStream) CloseSend() error {
+	err := s.ClientStream.CloseSend()
+	if err != nil {
+		setSpanError(s.span, err)
+	}
+	return err
+}
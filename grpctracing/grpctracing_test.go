@@ -0,0 +1,174 @@
+package grpctracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	jaeger "github.com/uber/jaeger-client-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUnaryServerInterceptor_ExtractsParentSpan(t *testing.T) {
+	tracer := mocktracer.New()
+
+	parent := tracer.StartSpan("parent")
+	md := metadata.MD{}
+	if err := tracer.Inject(parent.Context(), opentracing.TextMap, metadataTextMap(md)); err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	interceptor := UnaryServerInterceptor(tracer)
+
+	var serverSpan opentracing.Span
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		serverSpan = opentracing.SpanFromContext(ctx)
+		return "ok", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}
+
+	if _, err := interceptor(ctx, nil, info, handler); err != nil {
+		t.Fatalf("interceptor() error = %v", err)
+	}
+
+	mockSpan, ok := serverSpan.(*mocktracer.MockSpan)
+	if !ok {
+		t.Fatalf("handler did not observe a mock span in its context")
+	}
+	if mockSpan.ParentID != parent.(*mocktracer.MockSpan).SpanContext.SpanID {
+		t.Errorf("server span ParentID = %d, want %d", mockSpan.ParentID, parent.(*mocktracer.MockSpan).SpanContext.SpanID)
+	}
+	if mockSpan.OperationName != info.FullMethod {
+		t.Errorf("OperationName = %q, want %q", mockSpan.OperationName, info.FullMethod)
+	}
+}
+
+func TestUnaryServerInterceptor_TagsErrors(t *testing.T) {
+	tracer := mocktracer.New()
+	interceptor := UnaryServerInterceptor(tracer)
+
+	wantErr := errors.New("boom")
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, wantErr
+	}
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}, handler)
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("interceptor() error = %v, want %v", err, wantErr)
+	}
+
+	spans := tracer.FinishedSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 finished span, got %d", len(spans))
+	}
+	if got := spans[0].Tag(string(ext.Error)); got != true {
+		t.Errorf("expected the span to be tagged as an error")
+	}
+}
+
+func TestUnaryClientInterceptor_InjectsSpan(t *testing.T) {
+	tracer := mocktracer.New()
+	interceptor := UnaryClientInterceptor(tracer)
+
+	var gotCtx context.Context
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		gotCtx = ctx
+		return nil
+	}
+
+	if err := interceptor(context.Background(), "/svc/Method", nil, nil, nil, invoker); err != nil {
+		t.Fatalf("interceptor() error = %v", err)
+	}
+
+	md, ok := metadata.FromOutgoingContext(gotCtx)
+	if !ok {
+		t.Fatalf("invoker did not observe outgoing metadata")
+	}
+	if _, err := tracer.Extract(opentracing.TextMap, metadataTextMap(md)); err != nil {
+		t.Fatalf("Extract() error = %v, outgoing metadata did not carry the span", err)
+	}
+
+	spans := tracer.FinishedSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 finished span, got %d", len(spans))
+	}
+	if got := spans[0].Tag(string(ext.SpanKind)); got != ext.SpanKindRPCClientEnum {
+		t.Errorf("SpanKind tag = %v, want %v", got, ext.SpanKindRPCClientEnum)
+	}
+}
+
+// TestPassthroughDisconnectedSpanScenario reproduces the scenario a
+// passthrough interceptor exists for: a traced parent process starts a
+// span and puts its wire representation in the environment before
+// exec'ing a child that never initializes its own tracer. When that child
+// later makes an RPC back into a traced service, the server should still
+// see the parent's span as the RPC's parent instead of starting a
+// disconnected root span.
+func TestPassthroughDisconnectedSpanScenario(t *testing.T) {
+	tracer, closer := jaeger.NewTracer("test-service", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+	defer closer.Close()
+
+	parent := tracer.StartSpan("parent")
+	parentMD := metadata.MD{}
+	if err := tracer.Inject(parent.Context(), opentracing.TextMap, metadataTextMap(parentMD)); err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	traceHeader := parentMD.Get("uber-trace-id")[0]
+
+	t.Setenv("UBER_TRACE_ID", traceHeader)
+
+	// The child process captures the header at interceptor creation time,
+	// long before it ever calls an RPC.
+	clientInterceptor := NewPassthroughUnaryClientInterceptor()
+
+	var outgoingCtx context.Context
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		outgoingCtx = ctx
+		return nil
+	}
+	if err := clientInterceptor(context.Background(), "/svc/Method", nil, nil, nil, invoker); err != nil {
+		t.Fatalf("interceptor() error = %v", err)
+	}
+
+	// The traced service receives the child's call.
+	outgoingMD, _ := metadata.FromOutgoingContext(outgoingCtx)
+	incomingCtx := metadata.NewIncomingContext(context.Background(), outgoingMD)
+
+	var serverSpan opentracing.Span
+	serverInterceptor := UnaryServerInterceptor(tracer)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		serverSpan = opentracing.SpanFromContext(ctx)
+		return "ok", nil
+	}
+	if _, err := serverInterceptor(incomingCtx, nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}, handler); err != nil {
+		t.Fatalf("server interceptor() error = %v", err)
+	}
+
+	serverSpanCtx, ok := serverSpan.Context().(jaeger.SpanContext)
+	if !ok {
+		t.Fatalf("server span has unexpected context type %T", serverSpan.Context())
+	}
+	parentSpanCtx := parent.Context().(jaeger.SpanContext)
+	if serverSpanCtx.TraceID() != parentSpanCtx.TraceID() {
+		t.Errorf("server span TraceID = %v, want %v (parent should not be disconnected)", serverSpanCtx.TraceID(), parentSpanCtx.TraceID())
+	}
+	if serverSpanCtx.ParentID() != parentSpanCtx.SpanID() {
+		t.Errorf("server span ParentID = %v, want %v", serverSpanCtx.ParentID(), parentSpanCtx.SpanID())
+	}
+}
+
+func TestPassthroughHeaderFromEnviron(t *testing.T) {
+	header, value, ok := passthroughHeaderFromEnviron([]string{"PATH=/bin", "UBER_TRACE_ID=abc:def:0:1", "OTHER=1"})
+	if !ok || header != "uber-trace-id" || value != "abc:def:0:1" {
+		t.Fatalf("got (%q, %q, %v), want (uber-trace-id, abc:def:0:1, true)", header, value, ok)
+	}
+
+	if _, _, ok := passthroughHeaderFromEnviron([]string{"PATH=/bin"}); ok {
+		t.Fatalf("expected no header to be found")
+	}
+}
@@ -0,0 +1,98 @@
+package grpctracing
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// passthroughHeaders lists the metadata keys the passthrough interceptors
+// know how to forward, in the priority order incoming metadata is searched.
+// The environment is searched in its own order instead: the first
+// recognized variable in os.Environ() wins.
+var passthroughHeaders = []string{"uber-trace-id", "traceparent"}
+
+// passthroughEnvVars maps the environment variable a process might have
+// inherited from its parent to the gRPC metadata key it corresponds to.
+var passthroughEnvVars = map[string]string{
+	"UBER_TRACE_ID": "uber-trace-id",
+	"TRACEPARENT":   "traceparent",
+}
+
+// PassthroughOption configures where NewPassthroughUnaryClientInterceptor
+// and NewPassthroughStreamClientInterceptor look for a trace context to
+// forward.
+type PassthroughOption func(*passthroughOptions)
+
+type passthroughOptions struct {
+	incoming metadata.MD
+}
+
+// WithIncomingMetadata seeds the passthrough header from md - typically the
+// metadata of a request this process already received as a server - taking
+// priority over the environment.
+func WithIncomingMetadata(md metadata.MD) PassthroughOption {
+	return func(o *passthroughOptions) {
+		o.incoming = md
+	}
+}
+
+// NewPassthroughUnaryClientInterceptor returns a grpc.UnaryClientInterceptor
+// for processes that don't initialize their own tracer but still need to
+// forward a trace context they inherited, so RPCs they make back into a
+// traced service don't show up as disconnected spans. The header is
+// captured once, at interceptor creation, from the incoming metadata (see
+// WithIncomingMetadata) or failing that from the process environment, and
+// written unchanged into every outgoing call.
+func NewPassthroughUnaryClientInterceptor(opts ...PassthroughOption) grpc.UnaryClientInterceptor {
+	header, value, ok := capturePassthroughHeader(opts...)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		if ok {
+			ctx = metadata.AppendToOutgoingContext(ctx, header, value)
+		}
+		return invoker(ctx, method, req, reply, cc, callOpts...)
+	}
+}
+
+// NewPassthroughStreamClientInterceptor is the streaming equivalent of
+// NewPassthroughUnaryClientInterceptor.
+func NewPassthroughStreamClientInterceptor(opts ...PassthroughOption) grpc.StreamClientInterceptor {
+	header, value, ok := capturePassthroughHeader(opts...)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if ok {
+			ctx = metadata.AppendToOutgoingContext(ctx, header, value)
+		}
+		return streamer(ctx, desc, cc, method, callOpts...)
+	}
+}
+
+func capturePassthroughHeader(opts ...PassthroughOption) (header, value string, ok bool) {
+	var options passthroughOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	for _, header := range passthroughHeaders {
+		if vals := options.incoming.Get(header); len(vals) > 0 && vals[0] != "" {
+			return header, vals[0], true
+		}
+	}
+
+	return passthroughHeaderFromEnviron(os.Environ())
+}
+
+func passthroughHeaderFromEnviron(environ []string) (header, value string, ok bool) {
+	for _, kv := range environ {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			continue
+		}
+		if header, known := passthroughEnvVars[parts[0]]; known {
+			return header, parts[1], true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,71 @@
+package grpctracing
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that starts a
+// span for every incoming unary call, becoming a child of the span encoded
+// in the request metadata when one is present.
+func UnaryServerInterceptor(tracer opentracing.Tracer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		span := startServerSpan(ctx, tracer, info.FullMethod)
+		defer span.Finish()
+
+		resp, err := handler(opentracing.ContextWithSpan(ctx, span), req)
+		if err != nil {
+			setSpanError(span, err)
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor analogous
+// to UnaryServerInterceptor for streaming calls.
+func StreamServerInterceptor(tracer opentracing.Tracer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		span := startServerSpan(ss.Context(), tracer, info.FullMethod)
+		defer span.Finish()
+
+		err := handler(srv, &tracedServerStream{
+			ServerStream: ss,
+			ctx:          opentracing.ContextWithSpan(ss.Context(), span),
+		})
+		if err != nil {
+			setSpanError(span, err)
+		}
+		return err
+	}
+}
+
+func startServerSpan(ctx context.Context, tracer opentracing.Tracer, fullMethod string) opentracing.Span {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		md = metadata.MD{}
+	}
+
+	parentCtx, _ := tracer.Extract(opentracing.TextMap, metadataTextMap(md))
+	return tracer.StartSpan(fullMethod, ext.RPCServerOption(parentCtx))
+}
+
+func setSpanError(span opentracing.Span, err error) {
+	ext.Error.Set(span, true)
+	span.LogFields(log.Error(err))
+}
+
+// tracedServerStream wraps a grpc.ServerStream so handlers observe a
+// context carrying the request span via ss.Context().
+type tracedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tracedServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,29 @@
+package grpctracing
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// metadataTextMap adapts grpc metadata.MD to opentracing.TextMapReader and
+// opentracing.TextMapWriter so a tracer can Inject/Extract through it.
+type metadataTextMap metadata.MD
+
+// Set implements opentracing.TextMapWriter.
+func (m metadataTextMap) Set(key, val string) {
+	key = strings.ToLower(key)
+	m[key] = append(m[key], val)
+}
+
+// ForeachKey implements opentracing.TextMapReader.
+func (m metadataTextMap) ForeachKey(handler func(key, val string) error) error {
+	for k, vals := range m {
+		for _, v := range vals {
+			if err := handler(k, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// Package metrics wraps an opentracing.Tracer so every span it starts also
+// feeds Prometheus RED (rate, errors, duration) metrics, without requiring
+// any changes to code that already just calls tracer.StartSpan.
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsTracer wraps an opentracing.Tracer, recording Prometheus counters
+// and a duration histogram for every span it starts, keyed on operation
+// name and - when present - the ext.HTTPStatusCode tag. Passing a
+// MetricsTracer to OpenTracerGinMiddleware or OpenTracerGorestMiddleware is
+// enough to get status-code-labeled latency for free.
+type MetricsTracer struct {
+	opentracing.Tracer
+
+	spansStarted  *prometheus.CounterVec
+	spansFinished *prometheus.CounterVec
+	spanDuration  *prometheus.HistogramVec
+	spansInflight *prometheus.GaugeVec
+
+	gatherer prometheus.Gatherer
+}
+
+// NewMetricsTracer wraps tracer with the default otintegration metrics.
+func NewMetricsTracer(tracer opentracing.Tracer) *MetricsTracer {
+	return &MetricsTracer{
+		Tracer: tracer,
+		spansStarted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "otintegration_spans_started_total",
+			Help: "Total number of spans started.",
+		}, []string{"operation"}),
+		spansFinished: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "otintegration_spans_finished_total",
+			Help: "Total number of spans finished, labeled by HTTP status when known.",
+		}, []string{"operation", "http_status"}),
+		spanDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "otintegration_span_duration_seconds",
+			Help:    "Span duration in seconds, labeled by HTTP status when known.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"operation", "http_status"}),
+		spansInflight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "otintegration_spans_inflight",
+			Help: "Number of spans currently in flight.",
+		}, []string{"operation"}),
+	}
+}
+
+// Register registers the tracer's collectors with r. If r also implements
+// prometheus.Gatherer - as prometheus.NewRegistry() and
+// prometheus.DefaultRegisterer both do - Handler will scrape r, not
+// whatever was passed to some other call; otherwise Handler falls back to
+// prometheus.DefaultGatherer.
+func (t *MetricsTracer) Register(r prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{t.spansStarted, t.spansFinished, t.spanDuration, t.spansInflight} {
+		if err := r.Register(c); err != nil {
+			return err
+		}
+	}
+	if g, ok := r.(prometheus.Gatherer); ok {
+		t.gatherer = g
+	}
+	return nil
+}
+
+// Handler returns an http.Handler serving the Prometheus text exposition
+// format for the collectors registered with Register, scraping the same
+// registry they were registered with when that registry is also a
+// prometheus.Gatherer, and prometheus.DefaultGatherer otherwise.
+func (t *MetricsTracer) Handler() http.Handler {
+	gatherer := t.gatherer
+	if gatherer == nil {
+		gatherer = prometheus.DefaultGatherer
+	}
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+}
+
+// StartSpan implements opentracing.Tracer. The returned span wraps the
+// underlying tracer's span so that Finish/FinishWithOptions record metrics.
+func (t *MetricsTracer) StartSpan(operationName string, opts ...opentracing.StartSpanOption) opentracing.Span {
+	span := t.Tracer.StartSpan(operationName, opts...)
+
+	t.spansStarted.WithLabelValues(operationName).Inc()
+	t.spansInflight.WithLabelValues(operationName).Inc()
+
+	return &metricsSpan{
+		Span:           span,
+		tracer:         t,
+		operation:      operationName,
+		startOperation: operationName,
+		startTime:      time.Now(),
+	}
+}
+
+// metricsSpan wraps an opentracing.Span to observe the ext.HTTPStatusCode
+// tag as it's set and record metrics once the span finishes.
+type metricsSpan struct {
+	opentracing.Span
+
+	tracer *MetricsTracer
+
+	mu        sync.Mutex
+	operation string
+
+	// startOperation is the operation name spansInflight was incremented
+	// with, so Dec uses the same label even if operation is later
+	// changed via SetOperationName.
+	startOperation string
+	startTime      time.Time
+	httpStatus     string
+}
+
+// SetTag implements opentracing.Span, additionally remembering the
+// ext.HTTPStatusCode tag so it can label the metrics recorded at Finish.
+func (s *metricsSpan) SetTag(key string, value interface{}) opentracing.Span {
+	if key == string(ext.HTTPStatusCode) {
+		s.httpStatus = fmt.Sprintf("%v", value)
+	}
+	s.Span.SetTag(key, value)
+	return s
+}
+
+// SetOperationName implements opentracing.Span, additionally updating the
+// operation label used for metrics recorded at Finish. Middleware such as
+// OpenTracerGorestMiddleware's WithRouteResolver can only resolve a
+// low-cardinality route template after the request has been handled, and
+// renames the span at that point instead of naming it correctly up front;
+// without this override the metrics would keep labeling by the original,
+// possibly high-cardinality operation name.
+func (s *metricsSpan) SetOperationName(operationName string) opentracing.Span {
+	s.mu.Lock()
+	s.operation = operationName
+	s.mu.Unlock()
+	s.Span.SetOperationName(operationName)
+	return s
+}
+
+// Tracer implements opentracing.Span, returning the MetricsTracer so
+// further Inject/Extract calls keep going through it.
+func (s *metricsSpan) Tracer() opentracing.Tracer {
+	return s.tracer
+}
+
+// Finish implements opentracing.Span.
+func (s *metricsSpan) Finish() {
+	s.Span.Finish()
+	s.recordMetrics()
+}
+
+// FinishWithOptions implements opentracing.Span.
+func (s *metricsSpan) FinishWithOptions(opts opentracing.FinishOptions) {
+	s.Span.FinishWithOptions(opts)
+	s.recordMetrics()
+}
+
+func (s *metricsSpan) recordMetrics() {
+	s.mu.Lock()
+	operation := s.operation
+	s.mu.Unlock()
+
+	s.tracer.spansInflight.WithLabelValues(s.startOperation).Dec()
+	s.tracer.spansFinished.WithLabelValues(operation, s.httpStatus).Inc()
+	s.tracer.spanDuration.WithLabelValues(operation, s.httpStatus).Observe(time.Since(s.startTime).Seconds())
+}
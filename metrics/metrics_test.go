@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/P4elme6ka/otintegration"
+	"github.com/ant0ine/go-json-rest/rest"
+)
+
+func TestMetricsTracer_RecordsRequestMetrics(t *testing.T) {
+	tracer := NewMetricsTracer(mocktracer.New())
+
+	if started := testutil.ToFloat64(tracer.spansStarted.WithLabelValues("GET /users")); started != 0 {
+		t.Fatalf("spansStarted before any span = %v, want 0", started)
+	}
+
+	span := tracer.StartSpan("GET /users")
+	if got := testutil.ToFloat64(tracer.spansStarted.WithLabelValues("GET /users")); got != 1 {
+		t.Errorf("spansStarted after StartSpan = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(tracer.spansInflight.WithLabelValues("GET /users")); got != 1 {
+		t.Errorf("spansInflight after StartSpan = %v, want 1", got)
+	}
+
+	span.SetTag(string(ext.HTTPStatusCode), http.StatusOK)
+	span.Finish()
+
+	if got := testutil.ToFloat64(tracer.spansInflight.WithLabelValues("GET /users")); got != 0 {
+		t.Errorf("spansInflight after Finish = %v, want 0", got)
+	}
+	if got := testutil.ToFloat64(tracer.spansFinished.WithLabelValues("GET /users", "200")); got != 1 {
+		t.Errorf("spansFinished{http_status=200} = %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(tracer.spanDuration); got != 1 {
+		t.Errorf("spanDuration series count = %d, want 1", got)
+	}
+}
+
+func TestMetricsTracer_UnknownStatusLabel(t *testing.T) {
+	tracer := NewMetricsTracer(mocktracer.New())
+
+	span := tracer.StartSpan("background-job")
+	span.Finish()
+
+	if got := testutil.ToFloat64(tracer.spansFinished.WithLabelValues("background-job", "")); got != 1 {
+		t.Errorf("spansFinished{http_status=\"\"} = %v, want 1 for a span with no HTTP status tag", got)
+	}
+}
+
+func TestMetricsTracer_PassesThroughUnderlyingTracer(t *testing.T) {
+	inner := mocktracer.New()
+	tracer := NewMetricsTracer(inner)
+
+	span := tracer.StartSpan("op")
+	span.Finish()
+
+	spans := inner.FinishedSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected the wrapped tracer to see 1 finished span, got %d", len(spans))
+	}
+	if spans[0].OperationName != "op" {
+		t.Errorf("OperationName = %q, want op", spans[0].OperationName)
+	}
+}
+
+func TestMetricsTracer_Register(t *testing.T) {
+	tracer := NewMetricsTracer(mocktracer.New())
+	registry := prometheus.NewRegistry()
+
+	if err := tracer.Register(registry); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	span := tracer.StartSpan("op")
+	span.Finish()
+
+	metricFamilies, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	names := map[string]bool{}
+	for _, mf := range metricFamilies {
+		names[mf.GetName()] = true
+	}
+	for _, want := range []string{
+		"otintegration_spans_started_total",
+		"otintegration_spans_finished_total",
+		"otintegration_span_duration_seconds",
+		"otintegration_spans_inflight",
+	} {
+		if !names[want] {
+			t.Errorf("registry did not gather metric %q", want)
+		}
+	}
+}
+
+func TestMetricsTracer_HandlerScrapesRegisteredRegistry(t *testing.T) {
+	tracer := NewMetricsTracer(mocktracer.New())
+	registry := prometheus.NewRegistry()
+
+	if err := tracer.Register(registry); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	span := tracer.StartSpan("op")
+	span.Finish()
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	w := httptest.NewRecorder()
+	tracer.Handler().ServeHTTP(w, req)
+
+	if !strings.Contains(w.Body.String(), "otintegration_spans_started_total") {
+		t.Errorf("Handler() response did not contain otintegration_spans_started_total; got registered with a non-default registry, so it must scrape that registry, not prometheus.DefaultGatherer:\n%s", w.Body.String())
+	}
+}
+
+// TestMetricsTracer_GorestRouteResolverKeepsLowCardinality guards against
+// OpenTracerGorestMiddleware's WithRouteResolver renaming the underlying
+// opentracing.Span via SetOperationName after MetricsTracer.StartSpan has
+// already labeled its metrics by the original, high-cardinality raw path:
+// without MetricsTracer observing the rename, every distinct user ID would
+// get its own Prometheus series instead of sharing the route template.
+func TestMetricsTracer_GorestRouteResolverKeepsLowCardinality(t *testing.T) {
+	tracer := NewMetricsTracer(mocktracer.New())
+	registry := prometheus.NewRegistry()
+	if err := tracer.Register(registry); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	pathExpLookup := func(r *rest.Request) string {
+		return "/orgs/:orgId/users/:userId"
+	}
+
+	api := rest.NewApi()
+	api.Use(otintegration.OpenTracerGorestMiddleware("api", tracer,
+		otintegration.WithRouteResolver(), otintegration.WithGorestRouteTemplate(pathExpLookup)))
+	api.Use(&rest.RecorderMiddleware{})
+	router, err := rest.MakeRouter(
+		rest.Get("/orgs/:orgId/users/:userId", func(w rest.ResponseWriter, r *rest.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("MakeRouter() error = %v", err)
+	}
+	api.SetApp(router)
+
+	for _, userID := range []string{"1", "2", "3"} {
+		req := httptest.NewRequest(http.MethodGet, "/orgs/7/users/"+userID, nil)
+		w := httptest.NewRecorder()
+		api.MakeHandler().ServeHTTP(w, req)
+	}
+
+	const wantOperation = "api /orgs/:orgId/users/:userId"
+	if got := testutil.ToFloat64(tracer.spansFinished.WithLabelValues(wantOperation, "200")); got != 3 {
+		t.Errorf("spansFinished{operation=%q} = %v, want 3 (one series for the route template, not one per raw path)", wantOperation, got)
+	}
+	if got := testutil.CollectAndCount(tracer.spanDuration); got != 1 {
+		t.Errorf("spanDuration series count = %d, want 1 (one series for the route template, not one per raw path)", got)
+	}
+}
+
+var _ opentracing.Tracer = (*MetricsTracer)(nil)
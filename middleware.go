@@ -1,51 +1,229 @@
 package otintegration
 
 import (
+	"net/http"
+	"net/url"
+
 	"github.com/ant0ine/go-json-rest/rest"
 	"github.com/gin-gonic/gin"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 )
 
+// MiddlewareOption allows customizing the behavior of OpenTracerGinMiddleware
+// and OpenTracerGorestMiddleware without forking them.
+type MiddlewareOption func(*middlewareOptions)
+
+type middlewareOptions struct {
+	opNameFunc       func(r *http.Request) string
+	customOpNameFunc bool
+	urlTagFunc       func(u *url.URL) string
+	spanObserver     func(span opentracing.Span, r *http.Request)
+	componentName    string
+	errorTagFunc     func(statusCode int) bool
+	spanNameLimit    int
+	useRouteResolver bool
+	gorestRouteFunc  func(r *rest.Request) string
+}
+
+// WithOperationName overrides how the span operation name is derived from
+// the incoming request. By default it is `operationPrefix + " " + path`.
+// Takes precedence over WithRouteResolver.
+func WithOperationName(f func(r *http.Request) string) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.opNameFunc = f
+		o.customOpNameFunc = true
+	}
+}
+
+// WithURLTagFunc overrides the value recorded under the ext.HTTPUrl tag.
+// Useful for stripping query strings or high-cardinality IDs before they
+// reach the tracing backend.
+func WithURLTagFunc(f func(u *url.URL) string) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.urlTagFunc = f
+	}
+}
+
+// WithSpanObserver registers a callback invoked with the span right after it
+// is started, so callers can add their own tags or baggage.
+func WithSpanObserver(f func(span opentracing.Span, r *http.Request)) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.spanObserver = f
+	}
+}
+
+// WithComponentName sets the ext.Component tag on every span started by the
+// middleware.
+func WithComponentName(name string) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.componentName = name
+	}
+}
+
+// WithErrorTag overrides which status codes cause ext.Error to be set on the
+// span. By default any 5xx response is tagged as an error.
+func WithErrorTag(f func(statusCode int) bool) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.errorTagFunc = f
+	}
+}
+
+// WithSpanNameLimit truncates operation names longer than limit, appending
+// an ellipsis, to keep cardinality-sensitive tracing backends happy. The
+// default, 0, means unlimited.
+func WithSpanNameLimit(limit int) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.spanNameLimit = limit
+	}
+}
+
+// WithRouteResolver makes the operation name prefer the matched route
+// template (e.g. "/users/:id") over the raw request path, which keeps span
+// cardinality low for parameterized routes. Gin uses gin.Context.FullPath().
+// gorest has no equivalent - *rest.Request exposes the matched path
+// parameters but not the registered PathExp they came from, and guessing
+// the template back from parameter values is ambiguous whenever two
+// parameters share the same value (e.g. "/orgs/5/users/5") - so
+// OpenTracerGorestMiddleware also needs WithGorestRouteTemplate to resolve
+// anything; without it, gorest falls back to the raw path. Has no effect
+// when the route can't be resolved, or when WithOperationName is also set.
+// The ext.HTTPUrl tag still carries the raw path regardless of this option.
+func WithRouteResolver() MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.useRouteResolver = true
+	}
+}
+
+// WithGorestRouteTemplate gives OpenTracerGorestMiddleware a way to resolve
+// the matched route template for WithRouteResolver, since go-json-rest
+// doesn't expose the registered rest.Route/PathExp to middleware. f is
+// called with the request after it has reached the handler (so
+// r.PathParams is populated) and should return the PathExp of the route
+// that was actually matched, e.g. by looking it up in the same []rest.Route
+// table passed to rest.MakeRouter. Ignored by OpenTracerGinMiddleware.
+func WithGorestRouteTemplate(f func(r *rest.Request) string) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.gorestRouteFunc = f
+	}
+}
+
+// truncateSpanName enforces limit on name, matching the traefik approach of
+// truncating past the limit and appending an ellipsis. limit<=0 disables
+// truncation.
+func truncateSpanName(name string, limit int) string {
+	if limit <= 0 || len(name) <= limit {
+		return name
+	}
+	if limit <= 3 {
+		return name[:limit]
+	}
+	return name[:limit-3] + "..."
+}
+
+func defaultMiddlewareOptions(operationPrefix string) *middlewareOptions {
+	return &middlewareOptions{
+		opNameFunc: func(r *http.Request) string {
+			return operationPrefix + " " + r.URL.Path
+		},
+		urlTagFunc: func(u *url.URL) string {
+			return u.Path
+		},
+		spanObserver: func(span opentracing.Span, r *http.Request) {},
+		errorTagFunc: func(statusCode int) bool {
+			return statusCode >= http.StatusInternalServerError
+		},
+	}
+}
+
+func applyOptions(operationPrefix string, opts ...MiddlewareOption) *middlewareOptions {
+	options := defaultMiddlewareOptions(operationPrefix)
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
 // OpenTracerGinMiddleware - middleware that adds opentracing
-func OpenTracerGinMiddleware(operationPrefix string, tracer opentracing.Tracer) gin.HandlerFunc {
+func OpenTracerGinMiddleware(operationPrefix string, tracer opentracing.Tracer, opts ...MiddlewareOption) gin.HandlerFunc {
+	options := applyOptions(operationPrefix, opts...)
 	return func(c *gin.Context) {
 		// all before request is handled
+		operationName := options.opNameFunc(c.Request)
+		if options.useRouteResolver && !options.customOpNameFunc {
+			if fullPath := c.FullPath(); fullPath != "" {
+				operationName = operationPrefix + " " + fullPath
+			}
+		}
+		operationName = truncateSpanName(operationName, options.spanNameLimit)
+		urlTag := options.urlTagFunc(c.Request.URL)
+
 		var span opentracing.Span
 		if cspan, ok := c.Get(spanContextKey); ok {
-			span = StartSpanWithParent(tracer, cspan.(opentracing.Span).Context(), operationPrefix+" "+c.Request.URL.Path, c.Request.Method, c.Request.URL.Path)
+			span = StartSpanWithParent(tracer, cspan.(opentracing.Span).Context(), operationName, c.Request.Method, urlTag)
 
 		} else {
-			span = StartSpanWithHeader(tracer, &c.Request.Header, operationPrefix+" "+c.Request.URL.Path, c.Request.Method, c.Request.URL.Path)
+			span = StartSpanWithHeader(tracer, &c.Request.Header, operationName, c.Request.Method, urlTag)
 		}
 		defer span.Finish()
+
+		if options.componentName != "" {
+			ext.Component.Set(span, options.componentName)
+		}
+		options.spanObserver(span, c.Request)
+
 		c.Set(spanContextKey, span)
 		c.Next()
 
-		span.SetTag(string(ext.HTTPStatusCode), c.Writer.Status())
+		status := c.Writer.Status()
+		span.SetTag(string(ext.HTTPStatusCode), status)
+		if options.errorTagFunc(status) {
+			ext.Error.Set(span, true)
+		}
 	}
 }
 
 // OpenTracerGorestMiddleware - middleware that adds opentracing
-func OpenTracerGorestMiddleware(operationPrefix string, tracer opentracing.Tracer) rest.MiddlewareSimple {
+func OpenTracerGorestMiddleware(operationPrefix string, tracer opentracing.Tracer, opts ...MiddlewareOption) rest.MiddlewareSimple {
+	options := applyOptions(operationPrefix, opts...)
 	return func(next rest.HandlerFunc) rest.HandlerFunc {
 		return func(w rest.ResponseWriter, r *rest.Request) {
+			operationName := truncateSpanName(options.opNameFunc(r.Request), options.spanNameLimit)
+			urlTag := options.urlTagFunc(r.URL)
+
 			var span opentracing.Span
 			if cspan, ok := r.Env[spanContextKey]; ok {
-				span = StartSpanWithParent(tracer, cspan.(opentracing.Span).Context(), operationPrefix+" "+r.URL.Path, r.Method, r.URL.Path)
+				span = StartSpanWithParent(tracer, cspan.(opentracing.Span).Context(), operationName, r.Method, urlTag)
 
 			} else {
-				span = StartSpanWithHeader(tracer, &r.Header, operationPrefix+" "+r.URL.Path, r.Method, r.URL.Path)
+				span = StartSpanWithHeader(tracer, &r.Header, operationName, r.Method, urlTag)
 			}
 			defer span.Finish() // after all the other defers are completed, finish the span
 
+			if options.componentName != "" {
+				ext.Component.Set(span, options.componentName)
+			}
+			options.spanObserver(span, r.Request)
+
 			r.Env[spanContextKey] = span
 
 			next(w, r)
 
+			// The router only populates PathParams once the request has
+			// reached it, i.e. after next returns, so the route template
+			// can only be resolved here.
+			if options.useRouteResolver && !options.customOpNameFunc && options.gorestRouteFunc != nil {
+				if template := options.gorestRouteFunc(r); template != "" {
+					span.SetOperationName(truncateSpanName(operationPrefix+" "+template, options.spanNameLimit))
+				}
+			}
+
 			status, ok := r.Env["STATUS_CODE"].(int)
 			if ok {
 				span.SetTag(string(ext.HTTPStatusCode), status)
+				if options.errorTagFunc(status) {
+					ext.Error.Set(span, true)
+				}
 			}
 		}
 	}
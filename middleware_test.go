@@ -0,0 +1,333 @@
+package otintegration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/gin-gonic/gin"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func runGinMiddleware(t *testing.T, tracer *mocktracer.MockTracer, status int, opts ...MiddlewareOption) *mocktracer.MockSpan {
+	t.Helper()
+
+	router := gin.New()
+	router.Use(OpenTracerGinMiddleware("api", tracer, opts...))
+	router.GET("/users/:id", func(c *gin.Context) {
+		c.Status(status)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42?token=secret", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	spans := tracer.FinishedSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 finished span, got %d", len(spans))
+	}
+	return spans[0]
+}
+
+// runGorestMiddleware registers "/orgs/:orgId/users/:userId" behind
+// OpenTracerGorestMiddleware and serves a single request to it, so tests
+// can reuse the same route, including requests where orgId and userId have
+// the same value.
+func runGorestMiddleware(t *testing.T, tracer *mocktracer.MockTracer, path string, status int, opts ...MiddlewareOption) *mocktracer.MockSpan {
+	t.Helper()
+
+	api := rest.NewApi()
+	api.Use(OpenTracerGorestMiddleware("api", tracer, opts...))
+	api.Use(&rest.RecorderMiddleware{})
+	router, err := rest.MakeRouter(
+		rest.Get("/orgs/:orgId/users/:userId", func(w rest.ResponseWriter, r *rest.Request) {
+			w.WriteHeader(status)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("MakeRouter() error = %v", err)
+	}
+	api.SetApp(router)
+
+	req := httptest.NewRequest(http.MethodGet, path+"?token=secret", nil)
+	w := httptest.NewRecorder()
+	api.MakeHandler().ServeHTTP(w, req)
+
+	spans := tracer.FinishedSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 finished span, got %d", len(spans))
+	}
+	return spans[0]
+}
+
+func TestOpenTracerGinMiddleware_DefaultBehavior(t *testing.T) {
+	tracer := mocktracer.New()
+	span := runGinMiddleware(t, tracer, http.StatusOK)
+
+	if want := "api /users/42"; span.OperationName != want {
+		t.Errorf("OperationName = %q, want %q", span.OperationName, want)
+	}
+	if got := span.Tag(string(ext.HTTPUrl)); got != "/users/42" {
+		t.Errorf("HTTPUrl tag = %v, want /users/42", got)
+	}
+	if got := span.Tag(string(ext.HTTPStatusCode)); got != http.StatusOK {
+		t.Errorf("HTTPStatusCode tag = %v, want 200", got)
+	}
+	if _, hasError := span.Tags()[string(ext.Error)]; hasError {
+		t.Errorf("expected no error tag for a 200 response")
+	}
+}
+
+func TestOpenTracerGinMiddleware_WithOperationName(t *testing.T) {
+	tracer := mocktracer.New()
+	span := runGinMiddleware(t, tracer, http.StatusOK, WithOperationName(func(r *http.Request) string {
+		return "custom " + r.Method
+	}))
+
+	if want := "custom GET"; span.OperationName != want {
+		t.Errorf("OperationName = %q, want %q", span.OperationName, want)
+	}
+}
+
+func TestOpenTracerGinMiddleware_WithURLTagFunc(t *testing.T) {
+	tracer := mocktracer.New()
+	span := runGinMiddleware(t, tracer, http.StatusOK, WithURLTagFunc(func(u *url.URL) string {
+		u2 := *u
+		u2.RawQuery = ""
+		return u2.String()
+	}))
+
+	if got := span.Tag(string(ext.HTTPUrl)); got != "/users/42" {
+		t.Errorf("HTTPUrl tag = %v, want /users/42", got)
+	}
+}
+
+func TestOpenTracerGinMiddleware_WithSpanObserver(t *testing.T) {
+	tracer := mocktracer.New()
+	var observed opentracing.Span
+	span := runGinMiddleware(t, tracer, http.StatusOK, WithSpanObserver(func(s opentracing.Span, r *http.Request) {
+		observed = s
+		s.SetTag("custom.tag", "value")
+	}))
+
+	if observed == nil {
+		t.Fatalf("span observer was not called")
+	}
+	if got := span.Tag("custom.tag"); got != "value" {
+		t.Errorf("custom.tag = %v, want value", got)
+	}
+}
+
+func TestOpenTracerGinMiddleware_WithComponentName(t *testing.T) {
+	tracer := mocktracer.New()
+	span := runGinMiddleware(t, tracer, http.StatusOK, WithComponentName("my-service"))
+
+	if got := span.Tag(string(ext.Component)); got != "my-service" {
+		t.Errorf("Component tag = %v, want my-service", got)
+	}
+}
+
+func TestOpenTracerGinMiddleware_WithSpanNameLimit(t *testing.T) {
+	tracer := mocktracer.New()
+	span := runGinMiddleware(t, tracer, http.StatusOK, WithSpanNameLimit(10))
+
+	if want := "api /us..."; span.OperationName != want {
+		t.Errorf("OperationName = %q, want %q", span.OperationName, want)
+	}
+}
+
+func TestOpenTracerGinMiddleware_WithRouteResolver(t *testing.T) {
+	tracer := mocktracer.New()
+	span := runGinMiddleware(t, tracer, http.StatusOK, WithRouteResolver())
+
+	if want := "api /users/:id"; span.OperationName != want {
+		t.Errorf("OperationName = %q, want %q", span.OperationName, want)
+	}
+	if got := span.Tag(string(ext.HTTPUrl)); got != "/users/42" {
+		t.Errorf("HTTPUrl tag = %v, want /users/42", got)
+	}
+}
+
+func TestOpenTracerGinMiddleware_WithRouteResolver_CustomOperationNameWins(t *testing.T) {
+	tracer := mocktracer.New()
+	span := runGinMiddleware(t, tracer, http.StatusOK, WithRouteResolver(), WithOperationName(func(r *http.Request) string {
+		return "custom " + r.Method
+	}))
+
+	if want := "custom GET"; span.OperationName != want {
+		t.Errorf("OperationName = %q, want %q", span.OperationName, want)
+	}
+}
+
+func TestOpenTracerGinMiddleware_WithErrorTag(t *testing.T) {
+	tracer := mocktracer.New()
+
+	span := runGinMiddleware(t, tracer, http.StatusInternalServerError)
+	if got := span.Tag(string(ext.Error)); got != true {
+		t.Errorf("expected default error tag to be set for a 500 response, got %v", got)
+	}
+
+	tracer2 := mocktracer.New()
+	span2 := runGinMiddleware(t, tracer2, http.StatusNotFound, WithErrorTag(func(statusCode int) bool {
+		return statusCode == http.StatusNotFound
+	}))
+	if got := span2.Tag(string(ext.Error)); got != true {
+		t.Errorf("expected custom error tag to be set for a 404 response, got %v", got)
+	}
+}
+
+func TestOpenTracerGorestMiddleware_DefaultBehavior(t *testing.T) {
+	tracer := mocktracer.New()
+	span := runGorestMiddleware(t, tracer, "/orgs/7/users/42", http.StatusOK)
+
+	if want := "api /orgs/7/users/42"; span.OperationName != want {
+		t.Errorf("OperationName = %q, want %q", span.OperationName, want)
+	}
+	if got := span.Tag(string(ext.HTTPUrl)); got != "/orgs/7/users/42" {
+		t.Errorf("HTTPUrl tag = %v, want /orgs/7/users/42", got)
+	}
+	if got := span.Tag(string(ext.HTTPStatusCode)); got != http.StatusOK {
+		t.Errorf("HTTPStatusCode tag = %v, want 200", got)
+	}
+}
+
+func TestOpenTracerGorestMiddleware_WithOperationName(t *testing.T) {
+	tracer := mocktracer.New()
+	span := runGorestMiddleware(t, tracer, "/orgs/7/users/42", http.StatusOK, WithOperationName(func(r *http.Request) string {
+		return "custom " + r.Method
+	}))
+
+	if want := "custom GET"; span.OperationName != want {
+		t.Errorf("OperationName = %q, want %q", span.OperationName, want)
+	}
+}
+
+func TestOpenTracerGorestMiddleware_WithURLTagFunc(t *testing.T) {
+	tracer := mocktracer.New()
+	span := runGorestMiddleware(t, tracer, "/orgs/7/users/42", http.StatusOK, WithURLTagFunc(func(u *url.URL) string {
+		u2 := *u
+		u2.RawQuery = ""
+		return u2.String()
+	}))
+
+	if got := span.Tag(string(ext.HTTPUrl)); got != "/orgs/7/users/42" {
+		t.Errorf("HTTPUrl tag = %v, want /orgs/7/users/42", got)
+	}
+}
+
+func TestOpenTracerGorestMiddleware_WithSpanObserver(t *testing.T) {
+	tracer := mocktracer.New()
+	var observed opentracing.Span
+	span := runGorestMiddleware(t, tracer, "/orgs/7/users/42", http.StatusOK, WithSpanObserver(func(s opentracing.Span, r *http.Request) {
+		observed = s
+		s.SetTag("custom.tag", "value")
+	}))
+
+	if observed == nil {
+		t.Fatalf("span observer was not called")
+	}
+	if got := span.Tag("custom.tag"); got != "value" {
+		t.Errorf("custom.tag = %v, want value", got)
+	}
+}
+
+func TestOpenTracerGorestMiddleware_WithComponentName(t *testing.T) {
+	tracer := mocktracer.New()
+	span := runGorestMiddleware(t, tracer, "/orgs/7/users/42", http.StatusOK, WithComponentName("my-service"))
+
+	if got := span.Tag(string(ext.Component)); got != "my-service" {
+		t.Errorf("Component tag = %v, want my-service", got)
+	}
+}
+
+func TestOpenTracerGorestMiddleware_WithSpanNameLimit(t *testing.T) {
+	tracer := mocktracer.New()
+	span := runGorestMiddleware(t, tracer, "/orgs/7/users/42", http.StatusOK, WithSpanNameLimit(10))
+
+	if want := "api /or..."; span.OperationName != want {
+		t.Errorf("OperationName = %q, want %q", span.OperationName, want)
+	}
+}
+
+func TestOpenTracerGorestMiddleware_WithErrorTag(t *testing.T) {
+	tracer := mocktracer.New()
+
+	span := runGorestMiddleware(t, tracer, "/orgs/7/users/42", http.StatusInternalServerError)
+	if got := span.Tag(string(ext.Error)); got != true {
+		t.Errorf("expected default error tag to be set for a 500 response, got %v", got)
+	}
+
+	tracer2 := mocktracer.New()
+	span2 := runGorestMiddleware(t, tracer2, "/orgs/7/users/42", http.StatusNotFound, WithErrorTag(func(statusCode int) bool {
+		return statusCode == http.StatusNotFound
+	}))
+	if got := span2.Tag(string(ext.Error)); got != true {
+		t.Errorf("expected custom error tag to be set for a 404 response, got %v", got)
+	}
+}
+
+// gorestPathExpLookup returns a WithGorestRouteTemplate func for the fixed
+// route table runGorestMiddleware serves, the way a caller would resolve
+// the matched PathExp from their own []rest.Route.
+func gorestPathExpLookup(r *rest.Request) string {
+	return "/orgs/:orgId/users/:userId"
+}
+
+func TestOpenTracerGorestMiddleware_WithRouteResolver(t *testing.T) {
+	tracer := mocktracer.New()
+	span := runGorestMiddleware(t, tracer, "/orgs/7/users/42", http.StatusOK,
+		WithRouteResolver(), WithGorestRouteTemplate(gorestPathExpLookup))
+
+	if want := "api /orgs/:orgId/users/:userId"; span.OperationName != want {
+		t.Errorf("OperationName = %q, want %q", span.OperationName, want)
+	}
+	if got := span.Tag(string(ext.HTTPUrl)); got != "/orgs/7/users/42" {
+		t.Errorf("HTTPUrl tag = %v, want /orgs/7/users/42", got)
+	}
+}
+
+// TestOpenTracerGorestMiddleware_WithRouteResolver_EqualPathParamValues
+// guards against the bug where the template was reconstructed by matching
+// path segments against r.PathParams values: when two parameters share a
+// value, that approach picks a parameter name at random per request.
+// WithGorestRouteTemplate sidesteps this entirely since the template comes
+// from the caller, not from the parameter values.
+func TestOpenTracerGorestMiddleware_WithRouteResolver_EqualPathParamValues(t *testing.T) {
+	tracer := mocktracer.New()
+	span := runGorestMiddleware(t, tracer, "/orgs/5/users/5", http.StatusOK,
+		WithRouteResolver(), WithGorestRouteTemplate(gorestPathExpLookup))
+
+	if want := "api /orgs/:orgId/users/:userId"; span.OperationName != want {
+		t.Errorf("OperationName = %q, want %q", span.OperationName, want)
+	}
+}
+
+func TestOpenTracerGorestMiddleware_WithRouteResolver_NoTemplateFuncFallsBackToRawPath(t *testing.T) {
+	tracer := mocktracer.New()
+	span := runGorestMiddleware(t, tracer, "/orgs/7/users/42", http.StatusOK, WithRouteResolver())
+
+	if want := "api /orgs/7/users/42"; span.OperationName != want {
+		t.Errorf("OperationName = %q, want %q", span.OperationName, want)
+	}
+}
+
+func TestOpenTracerGorestMiddleware_WithRouteResolver_CustomOperationNameWins(t *testing.T) {
+	tracer := mocktracer.New()
+	span := runGorestMiddleware(t, tracer, "/orgs/7/users/42", http.StatusOK,
+		WithRouteResolver(), WithGorestRouteTemplate(gorestPathExpLookup),
+		WithOperationName(func(r *http.Request) string {
+			return "custom " + r.Method
+		}))
+
+	if want := "custom GET"; span.OperationName != want {
+		t.Errorf("OperationName = %q, want %q", span.OperationName, want)
+	}
+}
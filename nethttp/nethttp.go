@@ -0,0 +1,72 @@
+// Package nethttp brings otintegration's tracing to plain net/http servers
+// and clients, for users of chi, echo, http.ServeMux or anything else built
+// on http.Handler/http.RoundTripper rather than Gin or go-json-rest.
+package nethttp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/P4elme6ka/otintegration"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+type contextKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying span, retrievable with
+// SpanFromContext.
+func ContextWithSpan(ctx context.Context, span opentracing.Span) context.Context {
+	return context.WithValue(ctx, contextKey{}, span)
+}
+
+// SpanFromContext returns the span stored in ctx by ContextWithSpan or
+// Middleware, if any.
+func SpanFromContext(ctx context.Context) (opentracing.Span, bool) {
+	span, ok := ctx.Value(contextKey{}).(opentracing.Span)
+	return span, ok
+}
+
+// Middleware returns an http.Handler wrapper equivalent to
+// otintegration.OpenTracerGinMiddleware/OpenTracerGorestMiddleware for
+// stdlib-compatible routers: it starts a span per request, becoming a
+// child of any span already in the request context and otherwise
+// extracting a parent from the incoming headers, tags it with the HTTP
+// status code, and stores it in the request context for downstream
+// handlers.
+func Middleware(tracer opentracing.Tracer, operationPrefix string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			operationName := operationPrefix + " " + r.URL.Path
+
+			var span opentracing.Span
+			if parent, ok := SpanFromContext(r.Context()); ok {
+				span = otintegration.StartSpanWithParent(tracer, parent.Context(), operationName, r.Method, r.URL.Path)
+			} else {
+				span = otintegration.StartSpanWithHeader(tracer, &r.Header, operationName, r.Method, r.URL.Path)
+			}
+			defer span.Finish()
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r.WithContext(ContextWithSpan(r.Context(), span)))
+
+			span.SetTag(string(ext.HTTPStatusCode), rec.status)
+			if rec.status >= http.StatusInternalServerError {
+				ext.Error.Set(span, true)
+			}
+		})
+	}
+}
+
+// statusRecorder captures the status code written through an
+// http.ResponseWriter so it can be tagged on the span after the handler
+// returns.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
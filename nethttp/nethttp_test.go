@@ -0,0 +1,105 @@
+package nethttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestRoundTrip_PropagatesSpanAcrossFakeClientAndServer(t *testing.T) {
+	tracer := mocktracer.New()
+
+	var serverSpan opentracing.Span
+	mux := http.NewServeMux()
+	mux.Handle("/users", Middleware(tracer, "api")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverSpan, _ = SpanFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(tracer, nil)}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/users", nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("client.Do() error = %v", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	if serverSpan == nil {
+		t.Fatalf("handler did not observe a span in its context")
+	}
+	serverMockSpan, ok := serverSpan.(*mocktracer.MockSpan)
+	if !ok {
+		t.Fatalf("server span has unexpected type %T", serverSpan)
+	}
+
+	finished := tracer.FinishedSpans()
+	var clientMockSpan *mocktracer.MockSpan
+	for _, s := range finished {
+		if s.SpanContext.SpanID != serverMockSpan.SpanContext.SpanID {
+			clientMockSpan = s
+		}
+	}
+	if clientMockSpan == nil {
+		t.Fatalf("did not find a finished client span")
+	}
+
+	if serverMockSpan.ParentID != clientMockSpan.SpanContext.SpanID {
+		t.Errorf("server span ParentID = %d, want %d (the client span's ID)", serverMockSpan.ParentID, clientMockSpan.SpanContext.SpanID)
+	}
+	if got := clientMockSpan.Tag(string(ext.HTTPStatusCode)); got != http.StatusOK {
+		t.Errorf("client span HTTPStatusCode tag = %v, want 200", got)
+	}
+}
+
+func TestMiddleware_TagsServerErrors(t *testing.T) {
+	tracer := mocktracer.New()
+
+	handler := Middleware(tracer, "api")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/boom", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	spans := tracer.FinishedSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 finished span, got %d", len(spans))
+	}
+	if got := spans[0].Tag(string(ext.Error)); got != true {
+		t.Errorf("expected the span to be tagged as an error for a 500 response")
+	}
+}
+
+func TestContextWithSpan(t *testing.T) {
+	tracer := mocktracer.New()
+	span := tracer.StartSpan("op")
+
+	if _, ok := SpanFromContext(context.Background()); ok {
+		t.Fatalf("SpanFromContext() on an empty context should not find a span")
+	}
+
+	ctx := ContextWithSpan(context.Background(), span)
+	got, ok := SpanFromContext(ctx)
+	if !ok || got != span {
+		t.Fatalf("SpanFromContext() = (%v, %v), want (%v, true)", got, ok, span)
+	}
+}
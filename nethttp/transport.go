@@ -0,0 +1,71 @@
+package nethttp
+
+import (
+	"net/http"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// Transport is an http.RoundTripper that starts a client-kind span for
+// every outbound request and injects it into the request headers so the
+// receiving service's Middleware can pick it up as its parent.
+type Transport struct {
+	// Tracer is used to start and inject spans. Defaults to
+	// opentracing.GlobalTracer() when nil.
+	Tracer opentracing.Tracer
+
+	// Base is the underlying RoundTripper used to perform the request.
+	// Defaults to http.DefaultTransport when nil.
+	Base http.RoundTripper
+}
+
+// NewTransport wraps base with tracing, using tracer to start and inject
+// spans. A nil base defaults to http.DefaultTransport.
+func NewTransport(tracer opentracing.Tracer, base http.RoundTripper) *Transport {
+	return &Transport{Tracer: tracer, Base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tracer := t.Tracer
+	if tracer == nil {
+		tracer = opentracing.GlobalTracer()
+	}
+
+	opts := []opentracing.StartSpanOption{
+		ext.SpanKindRPCClient,
+		opentracing.Tag{Key: string(ext.HTTPMethod), Value: req.Method},
+		opentracing.Tag{Key: string(ext.HTTPUrl), Value: req.URL.String()},
+	}
+	if parent, ok := SpanFromContext(req.Context()); ok {
+		opts = append(opts, opentracing.ChildOf(parent.Context()))
+	}
+	span := tracer.StartSpan(req.Method+" "+req.URL.Path, opts...)
+	defer span.Finish()
+
+	req = req.Clone(req.Context())
+	if err := tracer.Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header)); err != nil {
+		ext.Error.Set(span, true)
+		span.LogFields(log.Error(err))
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.LogFields(log.Error(err))
+		return resp, err
+	}
+
+	span.SetTag(string(ext.HTTPStatusCode), resp.StatusCode)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		ext.Error.Set(span, true)
+	}
+	return resp, nil
+}
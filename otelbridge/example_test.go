@@ -0,0 +1,51 @@
+package otelbridge_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/P4elme6ka/otintegration"
+	"github.com/P4elme6ka/otintegration/otelbridge"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// This example shows the migration path for an existing OpenTracing/Gin
+// setup: otelbridge.Backend drops in wherever backend/jaeger or
+// backend/zipkin used to be, and OpenTracerGinMiddlewareWithTracing is
+// called exactly as before. The only thing that changes is where spans end
+// up - here an in-memory exporter stands in for a real OTLP collector, but
+// swapping it for go.opentelemetry.io/otel/exporters/otlp/otlptrace is all
+// that's needed to ship to one.
+func Example() {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer provider.Shutdown(context.Background())
+
+	tracing, err := otintegration.NewTracing("my-service", 0, &otelbridge.Backend{TracerProvider: provider})
+	if err != nil {
+		panic(err)
+	}
+	defer tracing.Close()
+
+	router := gin.New()
+	router.Use(otintegration.OpenTracerGinMiddlewareWithTracing("api", tracing))
+	router.GET("/users/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	fmt.Println(len(spans), spans[0].Name)
+	// Output: 1 api /users/42
+}
@@ -0,0 +1,136 @@
+// Package otelbridge lets otintegration emit into OpenTelemetry instead of,
+// or alongside, a native OpenTracing backend such as backend/jaeger or
+// backend/zipkin. OpenTracing is archived upstream; this package wraps an
+// existing otel trace.TracerProvider as an otintegration.Backend using
+// go.opentelemetry.io/otel/bridge/opentracing, so every span the rest of
+// this module starts is backed by a real OTel span and flows to whatever
+// exporters (OTLP, stdout, ...) the provider is configured with.
+package otelbridge
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	otelbridge "go.opentelemetry.io/otel/bridge/opentracing"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/P4elme6ka/otintegration"
+)
+
+// Backend implements otintegration.Backend by bridging into an existing
+// OTel TracerProvider rather than standing up a new tracing system, so
+// otintegration.NewTracing can be pointed at OTel with no other code
+// changes.
+type Backend struct {
+	// TracerProvider creates the OTel tracer backing every bridged span.
+	TracerProvider oteltrace.TracerProvider
+
+	// Propagator controls the wire format used by HTTPHeaders and Binary
+	// injection/extraction (see Tracer). Defaults to
+	// propagation.TraceContext{}, i.e. W3C traceparent/tracestate, when
+	// nil.
+	Propagator propagation.TextMapPropagator
+}
+
+// Setup implements otintegration.Backend. The returned io.Closer is a
+// no-op: the TracerProvider's lifecycle, and that of its exporters, is
+// owned by whoever constructed it, not by this Backend.
+func (b *Backend) Setup(componentName string) (opentracing.Tracer, io.Closer, error) {
+	if b.TracerProvider == nil {
+		return nil, nil, errors.New("otelbridge: Backend.TracerProvider must be set")
+	}
+
+	propagator := b.Propagator
+	if propagator == nil {
+		propagator = propagation.TraceContext{}
+	}
+
+	bridge, _ := otelbridge.NewTracerPair(b.TracerProvider.Tracer(componentName))
+	bridge.SetTextMapPropagator(propagator)
+
+	return &Tracer{BridgeTracer: bridge}, noopCloser{}, nil
+}
+
+// Tracer wraps the OTel bridge's *otelbridge.BridgeTracer, adding support
+// for opentracing.Binary on top of the HTTPHeaders/TextMap formats the
+// bridge already handles. This keeps otintegration.InjectToBinary,
+// otintegration.ExtractFromBinary and otintegration.StartSpanFromBinary
+// working unchanged: the bytes they read and write are now the same W3C
+// traceparent (and tracestate, if set) the Propagator would put in an HTTP
+// header, rather than a vendor-specific binary format.
+type Tracer struct {
+	*otelbridge.BridgeTracer
+}
+
+// Inject implements opentracing.Tracer.
+func (t *Tracer) Inject(sm opentracing.SpanContext, format interface{}, carrier interface{}) error {
+	if !isBinary(format) {
+		return t.BridgeTracer.Inject(sm, format, carrier)
+	}
+
+	info, ok := carrier.(otintegration.TraceInfo)
+	if !ok {
+		return opentracing.ErrInvalidCarrier
+	}
+
+	header := map[string][]string{}
+	if err := t.BridgeTracer.Inject(sm, opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header)); err != nil {
+		return err
+	}
+
+	info.Reset()
+	for key, vals := range header {
+		for _, val := range vals {
+			if _, err := info.Write([]byte(key + ":" + val + "\n")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Extract implements opentracing.Tracer.
+func (t *Tracer) Extract(format interface{}, carrier interface{}) (opentracing.SpanContext, error) {
+	if !isBinary(format) {
+		return t.BridgeTracer.Extract(format, carrier)
+	}
+
+	info, ok := carrier.(otintegration.TraceInfo)
+	if !ok {
+		return nil, opentracing.ErrInvalidCarrier
+	}
+	if !info.Check() {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+
+	raw, err := io.ReadAll(info)
+	if err != nil {
+		return nil, err
+	}
+
+	header := opentracing.HTTPHeadersCarrier{}
+	for _, line := range strings.Split(string(raw), "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		header.Set(parts[0], parts[1])
+	}
+
+	return t.BridgeTracer.Extract(opentracing.HTTPHeaders, header)
+}
+
+func isBinary(format interface{}) bool {
+	builtin, ok := format.(opentracing.BuiltinFormat)
+	return ok && builtin == opentracing.Binary
+}
+
+// noopCloser is the io.Closer handed back by Backend.Setup, since the
+// bridged TracerProvider outlives any one Backend and is closed by its own
+// owner.
+type noopCloser struct{}
+
+func (noopCloser) Close() error { return nil }
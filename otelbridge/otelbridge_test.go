@@ -0,0 +1,106 @@
+package otelbridge
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// traceInfoBuffer is the simplest otintegration.TraceInfo: an in-memory
+// buffer that reports itself non-empty once something has been written to
+// it.
+type traceInfoBuffer struct {
+	bytes.Buffer
+}
+
+func (b *traceInfoBuffer) Reset()      { b.Buffer.Reset() }
+func (b *traceInfoBuffer) Check() bool { return b.Buffer.Len() > 0 }
+
+func newTestBackend(t *testing.T) (*Backend, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() {
+		if err := provider.Shutdown(context.Background()); err != nil {
+			t.Errorf("provider.Shutdown() error = %v", err)
+		}
+	})
+	return &Backend{TracerProvider: provider}, exporter
+}
+
+func TestBackend_Setup(t *testing.T) {
+	backend, _ := newTestBackend(t)
+
+	tracer, closer, err := backend.Setup("my-service")
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	if err := closer.Close(); err != nil {
+		t.Errorf("closer.Close() error = %v", err)
+	}
+
+	span := tracer.StartSpan("op")
+	span.Finish()
+}
+
+func TestTracer_BinaryRoundTrip(t *testing.T) {
+	backend, exporter := newTestBackend(t)
+	tracer, _, err := backend.Setup("my-service")
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	span := tracer.StartSpan("parent")
+
+	var info traceInfoBuffer
+	if err := tracer.Inject(span.Context(), opentracing.Binary, &info); err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	if !info.Check() {
+		t.Fatalf("Inject() wrote no bytes to the carrier")
+	}
+
+	extracted, err := tracer.Extract(opentracing.Binary, &info)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+
+	child := tracer.StartSpan("child", opentracing.ChildOf(extracted))
+	child.Finish()
+	span.Finish()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 exported spans, got %d", len(spans))
+	}
+
+	var parentSpanID, childParentSpanID string
+	for _, s := range spans {
+		switch s.Name {
+		case "parent":
+			parentSpanID = s.SpanContext.SpanID().String()
+		case "child":
+			childParentSpanID = s.Parent.SpanID().String()
+		}
+	}
+	if childParentSpanID != parentSpanID {
+		t.Errorf("child's parent span ID = %q, want %q (the Binary round trip should preserve the OTel parent)", childParentSpanID, parentSpanID)
+	}
+}
+
+func TestTracer_ExtractBinary_EmptyCarrier(t *testing.T) {
+	backend, _ := newTestBackend(t)
+	tracer, _, err := backend.Setup("my-service")
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	var info traceInfoBuffer
+	if _, err := tracer.Extract(opentracing.Binary, &info); err != opentracing.ErrSpanContextNotFound {
+		t.Errorf("Extract() error = %v, want ErrSpanContextNotFound", err)
+	}
+}